@@ -0,0 +1,79 @@
+package elasticlogrus
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const ecsVersion = "1.12.0"
+
+// ServiceMeta is static metadata stamped onto every entry formatted by
+// NewECSFormatter, so it doesn't have to be attached log call by log call.
+type ServiceMeta struct {
+	Name     string
+	Version  string
+	Hostname string
+}
+
+// NewECSFormatter returns a Formatter that emits fields under the Elastic
+// Common Schema (https://www.elastic.co/guide/en/ecs/current/index.html):
+// "log.level" instead of "level", "error.message"/"error.stack_trace" for
+// the logrus error field, and entry.Data flattened under "labels.*". meta
+// is stamped onto every entry so callers don't need to attach
+// service/host identifiers to each log call themselves.
+func NewECSFormatter(meta ServiceMeta) Formatter {
+	return func(entry *logrus.Entry) Message {
+		msg := Message{
+			"@timestamp":  entry.Time.UTC().Format(time.RFC3339Nano),
+			"message":     entry.Message,
+			"log.level":   entry.Level.String(),
+			"ecs.version": ecsVersion,
+		}
+
+		if meta.Name != "" {
+			msg["service.name"] = meta.Name
+		}
+
+		if meta.Version != "" {
+			msg["service.version"] = meta.Version
+		}
+
+		if meta.Hostname != "" {
+			msg["host.hostname"] = meta.Hostname
+		}
+
+		errorKeyConsumed := false
+		if e, ok := entry.Data[logrus.ErrorKey]; ok && e != nil {
+			if err, ok := e.(error); ok {
+				msg["error.message"] = err.Error()
+				msg["error.stack_trace"] = fmt.Sprintf("%+v", err)
+				errorKeyConsumed = true
+			}
+		}
+
+		for k, v := range entry.Data {
+			if k == logrus.ErrorKey && errorKeyConsumed {
+				continue
+			}
+
+			flattenInto(msg, "labels."+k, v)
+		}
+
+		return msg
+	}
+}
+
+// ECSFormatter is an ECS formatter with no static service metadata.
+var ECSFormatter = NewECSFormatter(ServiceMeta{})
+
+func flattenInto(dst Message, prefix string, value interface{}) {
+	if nested, ok := value.(map[string]interface{}); ok {
+		for k, v := range nested {
+			flattenInto(dst, prefix+"."+k, v)
+		}
+		return
+	}
+
+	dst[prefix] = value
+}
@@ -0,0 +1,48 @@
+package elasticlogrus
+
+import (
+	"github.com/sirupsen/logrus"
+	"testing"
+)
+
+func TestECSFormatter(t *testing.T) {
+	entry := &logrus.Entry{
+		Message: "hello",
+		Level:   logrus.InfoLevel,
+		Data: logrus.Fields{
+			"component": "test",
+		},
+	}
+
+	msg := ECSFormatter(entry)
+
+	if msg["message"] != "hello" {
+		t.Errorf("expected message %q got %v", "hello", msg["message"])
+	}
+
+	if msg["log.level"] != "info" {
+		t.Errorf("expected log.level %q got %v", "info", msg["log.level"])
+	}
+
+	if msg["labels.component"] != "test" {
+		t.Errorf("expected labels.component %q got %v", "test", msg["labels.component"])
+	}
+}
+
+func TestNewECSFormatter_WithServiceMeta(t *testing.T) {
+	formatter := NewECSFormatter(ServiceMeta{Name: "my-service", Version: "1.0.0", Hostname: "host-1"})
+
+	msg := formatter(&logrus.Entry{Message: "hello", Level: logrus.InfoLevel})
+
+	if msg["service.name"] != "my-service" {
+		t.Errorf("expected service.name %q got %v", "my-service", msg["service.name"])
+	}
+
+	if msg["service.version"] != "1.0.0" {
+		t.Errorf("expected service.version %q got %v", "1.0.0", msg["service.version"])
+	}
+
+	if msg["host.hostname"] != "host-1" {
+		t.Errorf("expected host.hostname %q got %v", "host-1", msg["host.hostname"])
+	}
+}
@@ -2,15 +2,19 @@ package elasticlogrus
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"github.com/olivere/elastic/v7"
 	"github.com/sirupsen/logrus"
+	"io"
+	"strings"
 	"time"
 )
 
 type Hook struct {
 	client      *elastic.Client
 	index       string
+	indexFunc   IndexNameFunc
 	docType     string
 	levels      []logrus.Level
 	ctx         context.Context
@@ -18,8 +22,22 @@ type Hook struct {
 	formatter   Formatter
 	flushFunc   flushFunc
 	errorLogger ErrorLogger
+	typelessDoc bool
+	fallback    io.Writer
+	pipeline    string
+	router      RouterFunc
 }
 
+// IndexNameFunc resolves the target index for a single log entry, allowing
+// callers to roll into time-based indices (e.g. daily) without restarting.
+type IndexNameFunc func(*logrus.Entry) string
+
+// RouterFunc resolves both the target index and ingest pipeline for a
+// single log entry, overriding the hook's index resolver and pipeline for
+// that entry. This lets callers, for instance, send errors to a hot index
+// with a stack-trace-parsing pipeline while info logs go elsewhere.
+type RouterFunc func(entry *logrus.Entry) (index, pipeline string)
+
 type flushFunc func(*logrus.Entry, *Hook) error
 
 type Formatter func(*logrus.Entry) Message
@@ -42,12 +60,26 @@ func defaultFormatter(entry *logrus.Entry) Message {
 }
 
 func syncFlush(entry *logrus.Entry, hook *Hook) error {
-	_, err := hook.client.
+	msg := hook.formatter(entry)
+	index, pipeline := hook.resolveTarget(entry)
+
+	req := hook.client.
 		Index().
-		Index(hook.index).
-		Type(hook.docType).
-		BodyJson(hook.formatter(entry)).
-		Do(hook.ctx)
+		Index(index).
+		BodyJson(msg)
+
+	if docType, ok := hook.documentType(); ok {
+		req = req.Type(docType)
+	}
+
+	if pipeline != "" {
+		req = req.Pipeline(pipeline)
+	}
+
+	_, err := req.Do(hook.ctx)
+	if err != nil {
+		hook.writeFallback(msg)
+	}
 
 	return err
 }
@@ -63,21 +95,55 @@ func asyncFlush(entry *logrus.Entry, hook *Hook) error {
 	return nil
 }
 
+// New creates a Hook for index with no extra options. Use NewWithOptions
+// to pass a HookOption such as EnsureIndex.
 func New(client *elastic.Client, index string) (*Hook, error) {
+	return NewWithOptions(client, index)
+}
+
+// NewWithOptions creates a Hook for index, applying the given HookOptions.
+func NewWithOptions(client *elastic.Client, index string, opts ...HookOption) (*Hook, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	exists, err := client.IndexExists(index).Do(ctx)
-	if err != nil {
-		cancel()
-		return nil, err
+	config := &hookConfig{}
+	for _, opt := range opts {
+		opt(config)
 	}
 
-	if !exists {
-		cancel()
-		return nil, errors.New("index not exists")
+	// An index pattern (e.g. "logs-%Y.%m.%d") names a moving target, so
+	// tomorrow's index legitimately doesn't exist yet.
+	isPattern := strings.ContainsRune(index, '%')
+
+	if config.ensureIndex != nil {
+		if err := ensureIndexTemplate(ctx, client, index, *config.ensureIndex); err != nil {
+			cancel()
+			return nil, err
+		}
+
+		// A pattern names a moving target that ES creates lazily from the
+		// template as each day's/month's index is first written to; a
+		// static index is created up front, so it exists with the mapping
+		// and settings before the hook returns.
+		if !isPattern {
+			if err := createIndexIfMissing(ctx, client, index, *config.ensureIndex); err != nil {
+				cancel()
+				return nil, err
+			}
+		}
+	} else if !isPattern {
+		exists, err := client.IndexExists(index).Do(ctx)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		if !exists {
+			cancel()
+			return nil, errors.New("index not exists")
+		}
 	}
 
-	return &Hook{
+	hook := &Hook{
 		client:    client,
 		index:     index,
 		docType:   "doc",
@@ -86,7 +152,174 @@ func New(client *elastic.Client, index string) (*Hook, error) {
 		cancel:    cancel,
 		formatter: defaultFormatter,
 		flushFunc: syncFlush,
-	}, nil
+	}
+
+	if isPattern {
+		hook.SetIndexPattern(index)
+	}
+
+	return hook, nil
+}
+
+// resolveIndex returns the index to use for entry, consulting indexFunc
+// when set and falling back to the fixed index otherwise.
+func (h *Hook) resolveIndex(entry *logrus.Entry) string {
+	if h.indexFunc != nil {
+		return h.indexFunc(entry)
+	}
+
+	return h.index
+}
+
+// SetIndexPattern sets a strftime-style pattern (e.g. "logs-%Y.%m.%d") that
+// is resolved per entry, so a long-running service rolls into fresh
+// daily/monthly indices without restarting.
+func (h *Hook) SetIndexPattern(pattern string) {
+	h.indexFunc = func(entry *logrus.Entry) string {
+		return formatIndexPattern(pattern, entry.Time.UTC())
+	}
+}
+
+// SetIndexFunc sets a custom resolver for the target index of each entry.
+func (h *Hook) SetIndexFunc(fn IndexNameFunc) {
+	h.indexFunc = fn
+}
+
+// SetPipeline has both the single-doc and bulk index requests run through
+// the named ingest pipeline, for server-side enrichment such as geoip or
+// grok parsing.
+func (h *Hook) SetPipeline(name string) {
+	h.pipeline = name
+}
+
+// SetRouter overrides the index and pipeline resolution for every entry,
+// taking precedence over SetIndexPattern/SetIndexFunc/SetPipeline.
+func (h *Hook) SetRouter(fn RouterFunc) {
+	h.router = fn
+}
+
+// resolveTarget returns the index and ingest pipeline to use for entry.
+func (h *Hook) resolveTarget(entry *logrus.Entry) (index, pipeline string) {
+	if h.router != nil {
+		return h.router(entry)
+	}
+
+	return h.resolveIndex(entry), h.pipeline
+}
+
+// indexTemplatePattern returns the wildcard an index pattern like
+// "logs-%Y.%m.%d" expands to ("logs-*"), or index unchanged if it names a
+// single static index.
+func indexTemplatePattern(index string) string {
+	if i := strings.IndexRune(index, '%'); i >= 0 {
+		return index[:i] + "*"
+	}
+
+	return index
+}
+
+// indexTemplateName derives an index template name from the index pattern
+// it covers.
+func indexTemplateName(pattern string) string {
+	return strings.NewReplacer("%", "", "*", "", ".", "-").Replace(pattern) + "-template"
+}
+
+// indexSettingsBody fills in defaults for zero-valued IndexSettings fields
+// (1 shard, 1 replica, 1s refresh interval) and returns the ES "settings"
+// body.
+func indexSettingsBody(settings IndexSettings) map[string]interface{} {
+	shards := settings.Shards
+	if shards == 0 {
+		shards = 1
+	}
+
+	replicas := settings.Replicas
+	if replicas == 0 {
+		replicas = 1
+	}
+
+	refreshInterval := settings.RefreshInterval
+	if refreshInterval == "" {
+		refreshInterval = "1s"
+	}
+
+	return map[string]interface{}{
+		"number_of_shards":   shards,
+		"number_of_replicas": replicas,
+		"refresh_interval":   refreshInterval,
+	}
+}
+
+// indexMappingsBody is the default mapping applied by EnsureIndex. "level"
+// is a keyword so it can be filtered/aggregated on exactly, "message" is
+// full-text, and "data" is left dynamic since its shape varies with
+// whatever fields callers attach to a given log entry.
+func indexMappingsBody() map[string]interface{} {
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			"@timestamp": map[string]interface{}{"type": "date"},
+			"level":      map[string]interface{}{"type": "keyword"},
+			"message":    map[string]interface{}{"type": "text"},
+			"data": map[string]interface{}{
+				"type":    "object",
+				"dynamic": true,
+			},
+		},
+	}
+}
+
+// ensureIndexTemplate puts an index template covering index (or the
+// wildcard matching an index pattern) so ES creates matching indices with
+// the right mapping and settings as soon as the first document lands.
+func ensureIndexTemplate(ctx context.Context, client *elastic.Client, index string, settings IndexSettings) error {
+	pattern := indexTemplatePattern(index)
+
+	body := map[string]interface{}{
+		"index_patterns": []string{pattern},
+		"settings":       indexSettingsBody(settings),
+		"mappings":       indexMappingsBody(),
+	}
+
+	_, err := client.IndexPutTemplate(indexTemplateName(pattern)).BodyJson(body).Do(ctx)
+
+	return err
+}
+
+// createIndexIfMissing creates a static (non-pattern) index up front with
+// the same mapping/settings as the template, so it exists before the
+// first log entry is written - clusters with action.auto_create_index
+// disabled won't auto-create it at all.
+func createIndexIfMissing(ctx context.Context, client *elastic.Client, index string, settings IndexSettings) error {
+	exists, err := client.IndexExists(index).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"settings": indexSettingsBody(settings),
+		"mappings": indexMappingsBody(),
+	}
+
+	_, err = client.CreateIndex(index).BodyJson(body).Do(ctx)
+
+	return err
+}
+
+func formatIndexPattern(pattern string, t time.Time) string {
+	replacer := strings.NewReplacer(
+		"%Y", t.Format("2006"),
+		"%m", t.Format("01"),
+		"%d", t.Format("02"),
+		"%H", t.Format("15"),
+		"%M", t.Format("04"),
+		"%S", t.Format("05"),
+	)
+
+	return replacer.Replace(pattern)
 }
 
 func (h *Hook) SetLevel(level logrus.Level) {
@@ -108,6 +341,87 @@ func (h *Hook) SetDocumentType(t string) {
 	h.docType = t
 }
 
+// DisableDocumentType stops the hook from sending a _type on index
+// requests, which Elasticsearch 7 deprecates and Elasticsearch 8 rejects.
+func (h *Hook) DisableDocumentType() {
+	h.typelessDoc = true
+}
+
+// documentType returns the _type to stamp on index requests, and whether
+// one should be sent at all. Both the sync and bulk flush paths call this
+// so DisableDocumentType takes effect on each identically.
+func (h *Hook) documentType() (string, bool) {
+	if h.typelessDoc {
+		return "", false
+	}
+
+	return h.docType, true
+}
+
+// SetFallback gives the hook a durable audit trail for log entries that
+// fail to reach Elasticsearch: on delivery failure, the entry is
+// serialized as one JSON object per line and written to w (e.g.
+// os.Stderr, a file, a lumberjack rotator) instead of being dropped.
+// In bulk mode only the items that the bulk response marks as failed are
+// written; when a bulk request fails as a whole and no response comes
+// back, its entries are not written to w.
+func (h *Hook) SetFallback(w io.Writer) {
+	h.fallback = w
+}
+
+func (h *Hook) writeFallback(msg Message) {
+	if h.fallback == nil {
+		return
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		if h.errorLogger != nil {
+			h.errorLogger("couldn't marshal fallback message", err)
+		}
+		return
+	}
+
+	if _, err := h.fallback.Write(append(data, '\n')); err != nil && h.errorLogger != nil {
+		h.errorLogger("couldn't write fallback message", err)
+	}
+}
+
+// wrapBulkAfter wraps after so that, when a fallback writer is set, any
+// item the bulk processor reports as failed is reconstructed from its
+// original request and written to the fallback.
+func (h *Hook) wrapBulkAfter(after elastic.BulkAfterFunc) elastic.BulkAfterFunc {
+	return func(executionId int64, requests []elastic.BulkableRequest, response *elastic.BulkResponse, err error) {
+		if h.fallback != nil && response != nil {
+			for i, item := range response.Items {
+				if i >= len(requests) {
+					continue
+				}
+
+				for _, result := range item {
+					if result.Error == nil {
+						continue
+					}
+
+					lines, srcErr := requests[i].Source()
+					if srcErr != nil || len(lines) == 0 {
+						continue
+					}
+
+					doc := append([]byte(lines[len(lines)-1]), '\n')
+					if _, werr := h.fallback.Write(doc); werr != nil && h.errorLogger != nil {
+						h.errorLogger("couldn't write fallback message", werr)
+					}
+				}
+			}
+		}
+
+		if after != nil {
+			after(executionId, requests, response, err)
+		}
+	}
+}
+
 func (h *Hook) EnableBulkFlush(options *BulkOptions) error {
 	s := h.client.BulkProcessor().
 		Name("elasticlogrus")
@@ -137,7 +451,7 @@ func (h *Hook) EnableBulkFlush(options *BulkOptions) error {
 	}
 
 	processor, err := s.Before(options.BulkBefore).
-		After(options.BulkAfter).
+		After(h.wrapBulkAfter(options.BulkAfter)).
 		Do(context.Background())
 
 
@@ -156,11 +470,20 @@ func (h *Hook) EnableBulkFlush(options *BulkOptions) error {
 	}
 
 	h.flushFunc = func(entry *logrus.Entry, hook *Hook) error {
+		index, pipeline := hook.resolveTarget(entry)
+
 		req := elastic.NewBulkIndexRequest().
-			Index(hook.index).
-			Type(hook.docType).
+			Index(index).
 			Doc(hook.formatter(entry))
 
+		if docType, ok := hook.documentType(); ok {
+			req = req.Type(docType)
+		}
+
+		if pipeline != "" {
+			req = req.Pipeline(pipeline)
+		}
+
 		processor.Add(req)
 
 		return nil
@@ -0,0 +1,197 @@
+package elasticlogrus
+
+import (
+	"bytes"
+	"github.com/olivere/elastic/v7"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatIndexPattern(t *testing.T) {
+	ts := time.Date(2026, 7, 27, 13, 5, 9, 0, time.UTC)
+
+	got := formatIndexPattern("logs-%Y.%m.%d", ts)
+	want := "logs-2026.07.27"
+
+	if got != want {
+		t.Errorf("formatIndexPattern() = %q, want %q", got, want)
+	}
+}
+
+func TestIndexTemplatePattern(t *testing.T) {
+	cases := map[string]string{
+		"logs-%Y.%m.%d": "logs-*",
+		"logs":          "logs",
+	}
+
+	for index, want := range cases {
+		if got := indexTemplatePattern(index); got != want {
+			t.Errorf("indexTemplatePattern(%q) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestIndexTemplateName(t *testing.T) {
+	if got, want := indexTemplateName("logs-*"), "logs--template"; got != want {
+		t.Errorf("indexTemplateName() = %q, want %q", got, want)
+	}
+}
+
+func TestIndexSettingsBody_Defaults(t *testing.T) {
+	body := indexSettingsBody(IndexSettings{})
+
+	if body["number_of_shards"] != 1 {
+		t.Errorf("expected default number_of_shards 1, got %v", body["number_of_shards"])
+	}
+
+	if body["number_of_replicas"] != 1 {
+		t.Errorf("expected default number_of_replicas 1, got %v", body["number_of_replicas"])
+	}
+
+	if body["refresh_interval"] != "1s" {
+		t.Errorf("expected default refresh_interval 1s, got %v", body["refresh_interval"])
+	}
+}
+
+func TestIndexSettingsBody_Overrides(t *testing.T) {
+	body := indexSettingsBody(IndexSettings{Shards: 3, Replicas: 2, RefreshInterval: "30s"})
+
+	if body["number_of_shards"] != 3 {
+		t.Errorf("expected number_of_shards 3, got %v", body["number_of_shards"])
+	}
+
+	if body["number_of_replicas"] != 2 {
+		t.Errorf("expected number_of_replicas 2, got %v", body["number_of_replicas"])
+	}
+
+	if body["refresh_interval"] != "30s" {
+		t.Errorf("expected refresh_interval 30s, got %v", body["refresh_interval"])
+	}
+}
+
+func TestHook_WriteFallback(t *testing.T) {
+	var buf bytes.Buffer
+	hook := &Hook{}
+	hook.SetFallback(&buf)
+
+	hook.writeFallback(Message{"message": "boom"})
+
+	if !strings.Contains(buf.String(), `"message":"boom"`) {
+		t.Errorf("expected fallback to contain message, got %q", buf.String())
+	}
+
+	if !strings.HasSuffix(buf.String(), "\n") {
+		t.Error("expected fallback line to be newline-terminated")
+	}
+}
+
+func TestHook_WriteFallback_NoWriter(t *testing.T) {
+	hook := &Hook{}
+
+	// Must not panic when no fallback writer has been set.
+	hook.writeFallback(Message{"message": "boom"})
+}
+
+func TestHook_WrapBulkAfter_WritesFailedDocs(t *testing.T) {
+	var buf bytes.Buffer
+	hook := &Hook{}
+	hook.SetFallback(&buf)
+
+	requests := []elastic.BulkableRequest{
+		elastic.NewBulkIndexRequest().Index("logs").Doc(Message{"message": "ok"}),
+		elastic.NewBulkIndexRequest().Index("logs").Doc(Message{"message": "failed"}),
+	}
+
+	response := &elastic.BulkResponse{
+		Items: []map[string]*elastic.BulkResponseItem{
+			{"index": {Status: 201}},
+			{"index": {Status: 500, Error: &elastic.ErrorDetails{Reason: "boom"}}},
+		},
+	}
+
+	var afterCalled bool
+	after := hook.wrapBulkAfter(func(int64, []elastic.BulkableRequest, *elastic.BulkResponse, error) {
+		afterCalled = true
+	})
+
+	after(1, requests, response, nil)
+
+	if !afterCalled {
+		t.Error("expected the wrapped BulkAfterFunc to still be called")
+	}
+
+	if !strings.Contains(buf.String(), "failed") {
+		t.Errorf("expected fallback to contain the failed doc, got %q", buf.String())
+	}
+
+	if strings.Contains(buf.String(), `"ok"`) {
+		t.Errorf("expected the successful doc not to be written, got %q", buf.String())
+	}
+}
+
+func TestHook_ResolveTarget_RouterTakesPrecedence(t *testing.T) {
+	hook := &Hook{index: "default"}
+	hook.SetPipeline("default-pipeline")
+	hook.SetRouter(func(entry *logrus.Entry) (string, string) {
+		if entry.Level <= logrus.ErrorLevel {
+			return "hot", "parse-stack-trace"
+		}
+
+		return "warm", ""
+	})
+
+	index, pipeline := hook.resolveTarget(&logrus.Entry{Level: logrus.ErrorLevel})
+	if index != "hot" || pipeline != "parse-stack-trace" {
+		t.Errorf("resolveTarget() = (%q, %q), want (%q, %q)", index, pipeline, "hot", "parse-stack-trace")
+	}
+
+	index, pipeline = hook.resolveTarget(&logrus.Entry{Level: logrus.InfoLevel})
+	if index != "warm" || pipeline != "" {
+		t.Errorf("resolveTarget() = (%q, %q), want (%q, %q)", index, pipeline, "warm", "")
+	}
+}
+
+func TestHook_DocumentType(t *testing.T) {
+	hook := &Hook{docType: "doc"}
+
+	if docType, ok := hook.documentType(); !ok || docType != "doc" {
+		t.Errorf("documentType() = (%q, %v), want (%q, %v)", docType, ok, "doc", true)
+	}
+
+	hook.DisableDocumentType()
+
+	if docType, ok := hook.documentType(); ok {
+		t.Errorf("documentType() = (%q, %v), want ok=false once disabled", docType, ok)
+	}
+}
+
+func TestHook_DisableDocumentType_OmitsTypeFromBulkRequest(t *testing.T) {
+	hook := &Hook{docType: "doc"}
+	hook.DisableDocumentType()
+
+	req := elastic.NewBulkIndexRequest().Index("logs").Doc(Message{"message": "hi"})
+	if docType, ok := hook.documentType(); ok {
+		req = req.Type(docType)
+	}
+
+	lines, err := req.Source()
+	if err != nil {
+		t.Fatalf("Source() error: %v", err)
+	}
+
+	if strings.Contains(lines[0], `"_type"`) {
+		t.Errorf("expected no _type once disabled, got %q", lines[0])
+	}
+}
+
+func TestHook_ResolveTarget_NoRouterUsesIndexAndPipeline(t *testing.T) {
+	hook := &Hook{index: "default"}
+	hook.SetPipeline("enrich")
+
+	index, pipeline := hook.resolveTarget(&logrus.Entry{})
+	if index != "default" || pipeline != "enrich" {
+		t.Errorf("resolveTarget() = (%q, %q), want (%q, %q)", index, pipeline, "default", "enrich")
+	}
+}
@@ -19,3 +19,28 @@ type BulkOptions struct {
 
 	RetryItemStatusCodes []int
 }
+
+// IndexSettings configures the index template NewWithOptions puts in
+// place when the EnsureIndex option is used. Zero values fall back to
+// sane defaults (1 shard, 1 replica, 1s refresh interval).
+type IndexSettings struct {
+	Shards          int
+	Replicas        int
+	RefreshInterval string
+}
+
+// HookOption customizes a Hook at construction time, see NewWithOptions.
+type HookOption func(*hookConfig)
+
+type hookConfig struct {
+	ensureIndex *IndexSettings
+}
+
+// EnsureIndex has NewWithOptions put an index template (and, for a
+// static index name, the index itself) in place before the hook is used,
+// instead of requiring callers to pre-create the index themselves.
+func EnsureIndex(settings IndexSettings) HookOption {
+	return func(c *hookConfig) {
+		c.ensureIndex = &settings
+	}
+}